@@ -0,0 +1,84 @@
+// Copyright 2024 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "go.etcd.io/raft/v3/raftpb"
+
+// rangeFilter holds the bounds set by -from-index/-to-index, -from-term/
+// -to-term and -from-rev/-to-rev. A zero bound is unset (no lower/upper
+// limit on that axis).
+type rangeFilter struct {
+	fromIndex, toIndex uint64
+	fromTerm, toTerm   uint64
+	fromRev, toRev     int64
+}
+
+// matchesIndexTerm reports whether a raft log entry's (term, index) falls
+// within the configured bounds. This is checked before decoding the entry,
+// since it's the cheap, always-available filter.
+func (f rangeFilter) matchesIndexTerm(term, index uint64) bool {
+	if f.fromIndex != 0 && index < f.fromIndex {
+		return false
+	}
+	if f.toIndex != 0 && index > f.toIndex {
+		return false
+	}
+	if f.fromTerm != 0 && term < f.fromTerm {
+		return false
+	}
+	if f.toTerm != 0 && term > f.toTerm {
+		return false
+	}
+	return true
+}
+
+// matchesRevision reports whether de's mvcc revision, if it has one, falls
+// within [fromRev, toRev]. The WAL records raft log entries, not the mvcc
+// revisions an apply produces, so most entries don't carry one; such
+// entries are never excluded by a revision filter since there's no basis to
+// exclude them on.
+func (f rangeFilter) matchesRevision(de decodedEntry) bool {
+	if f.fromRev == 0 && f.toRev == 0 {
+		return true
+	}
+	rev, ok := de.revision()
+	if !ok {
+		return true
+	}
+	if f.fromRev != 0 && rev < f.fromRev {
+		return false
+	}
+	if f.toRev != 0 && rev > f.toRev {
+		return false
+	}
+	return true
+}
+
+// printMatchingEntry decodes ent only if it survives the cheap index/term
+// filter, and prints it only if it then survives the entry-type and
+// revision filters too.
+func printMatchingEntry(ent raftpb.Entry, rf rangeFilter, et *entryTypeFlag, redact redactMode, streamDecoderPath string, p printer) {
+	if !rf.matchesIndexTerm(ent.Term, ent.Index) {
+		return
+	}
+	de := decodeEntry(ent, streamDecoderPath, redact)
+	if !et.matches(de.Tags) {
+		return
+	}
+	if !rf.matchesRevision(de) {
+		return
+	}
+	p.PrintEntry(de)
+}
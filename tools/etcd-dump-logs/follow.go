@@ -0,0 +1,59 @@
+// Copyright 2024 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/server/v3/storage/wal"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+)
+
+// followPollInterval is how often -follow re-opens the WAL directory to
+// look for newly appended entries. Members don't append fast enough for
+// this to need to be any tighter, and polling more often just burns CPU
+// re-scanning segments that haven't changed.
+const followPollInterval = 1 * time.Second
+
+// followWAL tails waldir, printing newly appended entries as raft appends
+// them, starting right after from (the position of the last entry already
+// printed by the initial dump). It relies on the same wal.OpenForRead/
+// ReadAll path the initial dump uses: each poll re-opens the WAL seeked
+// just past the last entry seen, which transparently picks up entries
+// written into new segments since the last poll. It only returns on a
+// fatal read error; stop it by killing the process (e.g. Ctrl-C).
+func followWAL(lg *zap.Logger, waldir string, from walpb.Snapshot, rf rangeFilter, et *entryTypeFlag, redact redactMode, streamDecoderPath string, p printer) error {
+	pos := from
+	for {
+		time.Sleep(followPollInterval)
+
+		w, err := wal.OpenForRead(lg, waldir, pos)
+		if err != nil {
+			return err
+		}
+		_, _, ents, err := w.ReadAll()
+		w.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, ent := range ents {
+			printMatchingEntry(ent, rf, et, redact, streamDecoderPath, p)
+			pos.Index, pos.Term = ent.Index, ent.Term
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// TestNDJSONPrinterOneObjectPerLine pins down the property -format=ndjson
+// exists for: every entry is exactly one, independently-parseable JSON
+// object on its own line, in order, with the term/index/type fields a
+// consumer would jq/diff on. It deliberately doesn't assert the full
+// encoding of embedded protobuf messages (e.g. exact jsonpb field casing),
+// since that's determined by generated code this tree doesn't vendor.
+func TestNDJSONPrinterOneObjectPerLine(t *testing.T) {
+	var buf bytes.Buffer
+	p := newJSONPrinter(&buf, true /* ndjson */)
+
+	p.PrintHeader(nil, raftpb.HardState{Term: 1, Vote: 2, Commit: 3})
+	p.PrintEntry(decodedEntry{Term: 1, Index: 5, Type: "IRRPut", Message: &etcdserverpb.InternalRaftRequest{Put: &etcdserverpb.PutRequest{Key: []byte("foo")}}})
+	p.PrintEntry(decodedEntry{Term: 1, Index: 6, Type: "ConfigChange", Message: &raftpb.ConfChange{NodeID: 2}})
+	p.Close()
+
+	lines := splitNonEmptyLines(t, buf.String())
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3 (header + 2 entries):\n%s", len(lines), buf.String())
+	}
+
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("header line is not valid JSON: %v", err)
+	}
+	if kind := unquote(t, header["kind"]); kind != "header" {
+		t.Errorf("header kind = %q, want %q", kind, "header")
+	}
+
+	wantEntries := []struct {
+		term, index uint64
+		typ         string
+	}{
+		{1, 5, "IRRPut"},
+		{1, 6, "ConfigChange"},
+	}
+	for i, want := range wantEntries {
+		var entry struct {
+			Kind  string `json:"kind"`
+			Term  uint64 `json:"term"`
+			Index uint64 `json:"index"`
+			Type  string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(lines[i+1]), &entry); err != nil {
+			t.Fatalf("entry line %d is not valid JSON: %v", i, err)
+		}
+		if entry.Kind != "entry" || entry.Term != want.term || entry.Index != want.index || entry.Type != want.typ {
+			t.Errorf("entry line %d = %+v, want {kind:entry term:%d index:%d type:%s}", i, entry, want.term, want.index, want.typ)
+		}
+	}
+}
+
+func splitNonEmptyLines(t *testing.T, s string) []string {
+	t.Helper()
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewBufferString(s))
+	for sc.Scan() {
+		if line := sc.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("scanning output: %v", err)
+	}
+	return lines
+}
+
+func unquote(t *testing.T, raw json.RawMessage) string {
+	t.Helper()
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		t.Fatalf("unquoting %s: %v", raw, err)
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// TestRedactIRRCredentials exercises redactIRR directly against a decoded
+// InternalRaftRequest, rather than through the CLI and a golden file: the
+// shared WAL fixture in etcd-dump-log_test.go feeds every row of
+// TestEtcdDumpLogEntryType, including ones this tool doesn't own, so it's
+// not a safe place to grow new field coverage.
+func TestRedactIRRCredentials(t *testing.T) {
+	newIRR := func() *etcdserverpb.InternalRaftRequest {
+		return &etcdserverpb.InternalRaftRequest{
+			AuthUserAdd:            &etcdserverpb.AuthUserAddRequest{Name: "name1", Password: "pass1", HashedPassword: "hashedpass1"},
+			AuthUserChangePassword: &etcdserverpb.AuthUserChangePasswordRequest{Name: "name1", Password: "pass2", HashedPassword: "hashedpass2"},
+			Authenticate:           &etcdserverpb.InternalAuthenticateRequest{Name: "name1", Password: "password", SimpleToken: "token"},
+		}
+	}
+
+	for _, mode := range []redactMode{redactAuto, redactAll} {
+		irr := newIRR()
+		redactIRR(irr, mode)
+
+		if irr.AuthUserAdd.Password == "pass1" {
+			t.Errorf("mode %v: AuthUserAdd.Password was not redacted", mode)
+		}
+		if irr.AuthUserAdd.HashedPassword == "hashedpass1" {
+			t.Errorf("mode %v: AuthUserAdd.HashedPassword was not redacted", mode)
+		}
+		if irr.AuthUserChangePassword.Password == "pass2" {
+			t.Errorf("mode %v: AuthUserChangePassword.Password was not redacted", mode)
+		}
+		if irr.AuthUserChangePassword.HashedPassword == "hashedpass2" {
+			t.Errorf("mode %v: AuthUserChangePassword.HashedPassword was not redacted", mode)
+		}
+		if irr.Authenticate.Password == "password" {
+			t.Errorf("mode %v: Authenticate.Password was not redacted", mode)
+		}
+		if irr.Authenticate.SimpleToken == "token" {
+			t.Errorf("mode %v: Authenticate.SimpleToken was not redacted", mode)
+		}
+	}
+
+	irr := newIRR()
+	redactIRR(irr, redactNone)
+	if irr.AuthUserAdd.Password != "pass1" || irr.AuthUserAdd.HashedPassword != "hashedpass1" {
+		t.Errorf("redactNone must not modify the request, got %+v", irr.AuthUserAdd)
+	}
+}
+
+// TestRedactAllTxn asserts -redact=all reaches Put/Range/DeleteRange ops
+// nested inside a TxnRequest's Success and Failure branches, including a
+// nested TxnRequest, not just a top-level Put.
+func TestRedactAllTxn(t *testing.T) {
+	innerTxn := &etcdserverpb.TxnRequest{
+		Success: []*etcdserverpb.RequestOp{
+			{Request: &etcdserverpb.RequestOp_RequestPut{RequestPut: &etcdserverpb.PutRequest{Key: []byte("nested-key"), Value: []byte("nested-value")}}},
+		},
+	}
+	irr := &etcdserverpb.InternalRaftRequest{
+		Txn: &etcdserverpb.TxnRequest{
+			Success: []*etcdserverpb.RequestOp{
+				{Request: &etcdserverpb.RequestOp_RequestPut{RequestPut: &etcdserverpb.PutRequest{Key: []byte("key1"), Value: []byte("value1")}}},
+			},
+			Failure: []*etcdserverpb.RequestOp{
+				{Request: &etcdserverpb.RequestOp_RequestDeleteRange{RequestDeleteRange: &etcdserverpb.DeleteRangeRequest{Key: []byte("key2")}}},
+				{Request: &etcdserverpb.RequestOp_RequestTxn{RequestTxn: innerTxn}},
+			},
+		},
+	}
+
+	redactIRR(irr, redactAll)
+
+	successPut := irr.Txn.Success[0].Request.(*etcdserverpb.RequestOp_RequestPut).RequestPut
+	if string(successPut.Key) == "key1" || string(successPut.Value) == "value1" {
+		t.Errorf("Txn.Success Put was not redacted: %+v", successPut)
+	}
+
+	failureDel := irr.Txn.Failure[0].Request.(*etcdserverpb.RequestOp_RequestDeleteRange).RequestDeleteRange
+	if string(failureDel.Key) == "key2" {
+		t.Errorf("Txn.Failure DeleteRange was not redacted: %+v", failureDel)
+	}
+
+	nestedPut := innerTxn.Success[0].Request.(*etcdserverpb.RequestOp_RequestPut).RequestPut
+	if string(nestedPut.Key) == "nested-key" || string(nestedPut.Value) == "nested-value" {
+		t.Errorf("Put nested inside a Txn-within-a-Txn was not redacted: %+v", nestedPut)
+	}
+}